@@ -1,27 +1,43 @@
-package grpc
-
-import (
-	"Go-Design-Pattern-For-Real-World/Microservice/Vault"
-	"Go-Design-Pattern-For-Real-World/Microservice/Vault/pb"
-	grpctransport "github.com/go-kit/kit/transport/grpc"
-	"google.golang.org/grpc"
-)
-
-func New(conn *grpc.ClientConn) vault.Service {
-
-	var hashEndpoint = grpctransport.NewClient(conn, "Vault", "Hash",
-		vault.EncodeGRPCHashRequest,
-		vault.DecodeGRPCHashResponse,
-		pb.HashResponse{}).Endpoint()
-
-	var validateEndpoint = grpctransport.NewClient(
-		conn, "Vault", "Validate",
-		vault.EncodeGRPCValidateRequest,
-		vault.DecodeGRPCValidateResponse,
-		pb.ValidateResponse{}).Endpoint()
-
-	return vault.Endpoints{
-		HashEndpoint:     hashEndpoint,
-		ValidateEndpoint: validateEndpoint,
-	}
-}
+package grpc
+
+import (
+	"Microservice/Vault"
+	"Microservice/Vault/middleware"
+	"Microservice/Vault/pb"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+)
+
+// New wires up a Vault client backed by a single gRPC connection. tracer
+// lets every outbound call carry the span from the caller's context
+// (middleware.TracingClientOption) so it shows up as one trace alongside
+// the server-side spans recorded by NewGRPCServer.
+func New(conn *grpc.ClientConn, tracer stdopentracing.Tracer) vault.Service {
+
+	var hashEndpoint = grpctransport.NewClient(conn, pb.ServiceName, "Hash",
+		vault.EncodeGRPCHashRequest,
+		vault.DecodeGRPCHashResponse,
+		pb.HashResponse{},
+		middleware.TracingClientOption(tracer, "Hash")).Endpoint()
+
+	var validateEndpoint = grpctransport.NewClient(
+		conn, pb.ServiceName, "Validate",
+		vault.EncodeGRPCValidateRequest,
+		vault.DecodeGRPCValidateResponse,
+		pb.ValidateResponse{},
+		middleware.TracingClientOption(tracer, "Validate")).Endpoint()
+
+	var rotateEndpoint = grpctransport.NewClient(
+		conn, pb.ServiceName, "Rotate",
+		vault.EncodeGRPCRotateRequest,
+		vault.DecodeGRPCRotateResponse,
+		pb.RotateResponse{},
+		middleware.TracingClientOption(tracer, "Rotate")).Endpoint()
+
+	return vault.Endpoints{
+		HashEndpoint:     hashEndpoint,
+		ValidateEndpoint: validateEndpoint,
+		RotateEndpoint:   rotateEndpoint,
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/lb"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+
+	"Microservice/Vault"
+	"Microservice/Vault/middleware"
+	"Microservice/Vault/pb"
+)
+
+// defaultMaxAttempts/defaultRetryTimeout bound lb.Retry: a request is tried
+// against at most this many instances, and is abandoned altogether if none
+// of them have answered within the timeout.
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryTimeout = 500 * time.Millisecond
+)
+
+// NewWithDiscovery wires a Vault client to whatever instancer is doing
+// service discovery for it (Consul, etcd, DNS SRV, ...) instead of a single
+// *grpc.ClientConn. Each method gets its own sd.Endpointer - one gRPC
+// connection dialed per discovered instance - load balanced round robin and
+// retried across instances via lb.Retry, with a per-instance
+// circuitbreaker.Gobreaker so a failing instance stops being tried until it
+// recovers.
+func NewWithDiscovery(instancer sd.Instancer, tracer stdopentracing.Tracer, logger log.Logger) vault.Service {
+	return vault.Endpoints{
+		HashEndpoint:     discoveredEndpoint(instancer, tracer, logger, "Hash", vault.EncodeGRPCHashRequest, vault.DecodeGRPCHashResponse, pb.HashResponse{}),
+		ValidateEndpoint: discoveredEndpoint(instancer, tracer, logger, "Validate", vault.EncodeGRPCValidateRequest, vault.DecodeGRPCValidateResponse, pb.ValidateResponse{}),
+		RotateEndpoint:   discoveredEndpoint(instancer, tracer, logger, "Rotate", vault.EncodeGRPCRotateRequest, vault.DecodeGRPCRotateResponse, pb.RotateResponse{}),
+	}
+}
+
+// discoveredEndpoint builds the sd.Endpointer -> lb.Balancer -> lb.Retry
+// stack for a single RPC method, dialing a fresh *grpc.ClientConn for every
+// instance sd.NewEndpointer hands back.
+func discoveredEndpoint(
+	instancer sd.Instancer,
+	tracer stdopentracing.Tracer,
+	logger log.Logger,
+	method string,
+	enc grpctransport.EncodeRequestFunc,
+	dec grpctransport.DecodeResponseFunc,
+	reply interface{},
+) endpoint.Endpoint {
+
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		conn, err := grpc.Dial(instance, grpc.WithInsecure())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ep := grpctransport.NewClient(conn, pb.ServiceName, method, enc, dec, reply,
+			middleware.TracingClientOption(tracer, method)).Endpoint()
+		ep = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{Name: method}))(ep)
+
+		return ep, conn, nil
+	}
+
+	endpointer := sd.NewEndpointer(instancer, factory, logger)
+	balancer := lb.NewRoundRobin(endpointer)
+
+	return lb.Retry(defaultMaxAttempts, defaultRetryTimeout, balancer)
+}
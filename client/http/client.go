@@ -0,0 +1,71 @@
+// Package http is the HTTP/JSON counterpart to client/grpc: it builds a
+// vault.Service backed by service-discovered HTTP instances instead of a
+// gRPC connection, using the same go-kit sd/lb/circuitbreaker stack.
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	"github.com/go-kit/kit/sd/lb"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/sony/gobreaker"
+
+	"Microservice/Vault"
+)
+
+// defaultMaxAttempts/defaultRetryTimeout mirror client/grpc's: a request is
+// tried against at most this many instances, and abandoned if none answer
+// within the timeout.
+const (
+	defaultMaxAttempts  = 3
+	defaultRetryTimeout = 500 * time.Millisecond
+)
+
+// NewHTTPWithDiscovery wires a Vault client to whatever instancer is doing
+// service discovery for it (Consul, etcd, DNS SRV, ...), dialing a fresh
+// HTTP endpoint per discovered instance for each of /hash, /validate and
+// /rotate, round-robin load balanced, retried across instances via
+// lb.Retry, and circuit broken per instance via gobreaker.
+func NewHTTPWithDiscovery(instancer sd.Instancer, logger log.Logger) vault.Service {
+	return vault.Endpoints{
+		HashEndpoint:     discoveredEndpoint(instancer, logger, "hash", vault.EncodeHTTPHashRequest, vault.DecodeHTTPHashResponse),
+		ValidateEndpoint: discoveredEndpoint(instancer, logger, "validate", vault.EncodeHTTPValidateRequest, vault.DecodeHTTPValidateResponse),
+		RotateEndpoint:   discoveredEndpoint(instancer, logger, "rotate", vault.EncodeHTTPRotateRequest, vault.DecodeHTTPRotateResponse),
+	}
+}
+
+// discoveredEndpoint builds the sd.Endpointer -> lb.Balancer -> lb.Retry
+// stack for a single route, pointing a fresh httptransport.Client at
+// http://<instance>/<path> for every instance sd.NewEndpointer hands back.
+func discoveredEndpoint(
+	instancer sd.Instancer,
+	logger log.Logger,
+	path string,
+	enc httptransport.EncodeRequestFunc,
+	dec httptransport.DecodeResponseFunc,
+) endpoint.Endpoint {
+
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		tgt, err := url.Parse(fmt.Sprintf("http://%s/%s", instance, path))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ep := httptransport.NewClient("POST", tgt, enc, dec).Endpoint()
+		ep = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{Name: path}))(ep)
+
+		return ep, nil, nil
+	}
+
+	endpointer := sd.NewEndpointer(instancer, factory, logger)
+	balancer := lb.NewRoundRobin(endpointer)
+
+	return lb.Retry(defaultMaxAttempts, defaultRetryTimeout, balancer)
+}
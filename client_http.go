@@ -0,0 +1,62 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+/*
+	EncodeHTTP*Request/DecodeHTTP*Response are the client-side counterparts
+	to decodeHashRequest/decodeValidateRequest/decodeRotateRequest and
+	encodeResponse: where those decode an inbound *http.Request and encode
+	an outbound response on the server, these encode an outbound request
+	and decode an inbound response on the client, so
+	client/http.NewHTTPWithDiscovery can drive /hash, /validate, and
+	/rotate without needing access to this package's unexported request
+	and response types itself.
+*/
+
+func encodeHTTPRequest(r *http.Request, request interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+
+	r.Body = ioutil.NopCloser(&buf)
+
+	return nil
+}
+
+func EncodeHTTPHashRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	return encodeHTTPRequest(r, request)
+}
+
+func EncodeHTTPValidateRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	return encodeHTTPRequest(r, request)
+}
+
+func EncodeHTTPRotateRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	return encodeHTTPRequest(r, request)
+}
+
+func DecodeHTTPHashResponse(ctx context.Context, resp *http.Response) (interface{}, error) {
+	var res hashResponse
+	err := json.NewDecoder(resp.Body).Decode(&res)
+	return res, err
+}
+
+func DecodeHTTPValidateResponse(ctx context.Context, resp *http.Response) (interface{}, error) {
+	var res validateResponse
+	err := json.NewDecoder(resp.Body).Decode(&res)
+	return res, err
+}
+
+func DecodeHTTPRotateResponse(ctx context.Context, resp *http.Response) (interface{}, error) {
+	var res rotateResponse
+	err := json.NewDecoder(resp.Body).Decode(&res)
+	return res, err
+}
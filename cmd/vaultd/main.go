@@ -1,134 +1,228 @@
-import (
-	"flag"
-	"fmt"
-	"log"
-	"net"
-	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"Microservice/Vault"
-	"Microservice/Vault/pb"
-	"golang.org/x/net/context"
-	"google.golang.org/grpc"
-	/*
-		untuk membatasi maksimal akses ke endpoint tertentu
-	*/
-	ratelimitkit "github.com/go-kit/kit/ratelimit"
-)
-
-func main() {
-
-
-	/*
-		We use flags to allow the ops team to decide which endpoints we will listen on when
-		exposing the service on the network, 
-		but provide sensible defaults of 
-				:8080 for the JSON/HTTP server and 
-				:8081 for the gRPC server
-	*/
-	var (
-			httpAddr = flag.String("http", ":8080", "http listen address")
-			gRPCAddr = flag.String("grpc", ":8081", "gRPC listen address")
-	)
-
-	flag.Parse()
-
-	/*
-		We then create a new context using the context.Background() function, which returns a
-		non-nil, empty context that has no cancelation or deadline specified and contains no 
-		values, perfect for the base context of all of our services. Requests and middleware 
-		are free to create new context objects from this one in order to add request-scoped 
-		data or deadlines
-	*/
-	ctx := context.Background()
-
-	srv := Vault.NewService()
-	errChan := make(chan error)
-
-	/*
-		Buffered Channel
-
-		Untuk secara real time menerima kesalahan/error program
-	*/
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		errChan <- fmt.Errorf("%s", <-c)
-	}()
-
-
-	/*
-		Using Go Kit Endpoints
-	*/
-	hashEndpoint := vault.MakeHashEndpoint(srv)
-	validateEndpoint := vault.MakeValidateEndpoint(srv)
-
-	endpoints := vault.Endpoints{
-									HashEndpoint: hashEndpoint,
-									ValidateEndpoint: validateEndpoint,
-								}
-	/*
-		endpoint dengan rate limiter
-
-		hashEndpoint := vault.MakeHashEndpoint(srv){
-			hashEndpoint = ratelimitkit.NewTokenBucketLimiter(rlbucket)(hashEndpoint)
-		}
-
-		validateEndpoint := vault.MakeValidateEndpoint(srv){
-			validateEndpoint = ratelimitkit.NewTokenBucketLimiter(rlbucket)(validateEndpoint)
-		}	
-	
-	*/							
-
-	/*
-		Running HTTP Server
-	*/
-	go func() {
-
-		log.Println("http:", *httpAddr)
-		handler := vault.NewHTTPServer(ctx, endpoints)
-		errChan <- http.ListenAndServe(*httpAddr, handler)
-	}()
-
-
-	/*
-		Running gRPC Server
-	*/
-	go func() {
-		
-		listener, err := net.Listen("tcp", *gRPCAddr)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		log.Println("grpc:", *gRPCAddr)
-		handler := vault.NewGRPCServer(ctx, endpoints)
-
-		gRPCServer := grpc.NewServer()
-		pb.RegisterVaultServer(gRPCServer, handler)
-
-		errChan <- gRPCServer.Serve(listener)
-	}()
-}
-
-/*
-	We are going to use the NewTokenBucketLimiter middleware from Go kit's ratelimit package, and if we take a 
-	look at the code, we'll see how it uses closures and returns functions to inject a call to the token bucket
-	TakeAvailable method before passing execution to the next endpoint
-*/
-func NewTokenBucketLimiter(tb *ratelimit.Bucket) endpoint.Middleware {
-
-	return func(next endpoint.Endpoint) endpoint.Endpoint {
-		
-		return func(ctx context.Context, request interface{}) (interface{}, error) {
-
-			if tb.TakeAvailable(1) == 0 {
-				return nil, ErrLimited
-			}
-
-			return next(ctx, request)
-		}
-	}
-}
\ No newline at end of file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"Microservice/Vault"
+	"Microservice/Vault/hasher"
+	"Microservice/Vault/keyprovider"
+	"Microservice/Vault/middleware"
+	"Microservice/Vault/pb"
+	"Microservice/Vault/registrar"
+	kitlog "github.com/go-kit/kit/log"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-kit/kit/sd"
+	consulsd "github.com/go-kit/kit/sd/consul"
+	consulapi "github.com/hashicorp/consul/api"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func main() {
+
+
+	/*
+		We use flags to allow the ops team to decide which endpoints we will listen on when
+		exposing the service on the network, 
+		but provide sensible defaults of 
+				:8080 for the JSON/HTTP server and 
+				:8081 for the gRPC server
+	*/
+	var (
+			httpAddr = flag.String("http", ":8080", "http listen address")
+			gRPCAddr = flag.String("grpc", ":8081", "gRPC listen address")
+
+			// keyProvider picks how the HMAC pepper mixed into every
+			// password is sourced - see keyprovider.KeyProvider.
+			keyProvider = flag.String("key-provider", "none", "pepper key provider: vault, env, or none")
+			vaultAddr    = flag.String("vault-addr", "https://127.0.0.1:8200", "Vault address (-key-provider=vault)")
+			vaultToken   = flag.String("vault-token", "", "Vault token (-key-provider=vault)")
+			vaultKeyPath = flag.String("vault-key-path", "secret/data/vault-svc/pepper", "Vault KV v2 path holding the pepper (-key-provider=vault)")
+			envPepperVar = flag.String("env-pepper-var", "VAULT_PEPPER", "environment variable holding the pepper (-key-provider=env)")
+
+			// consulAddr, left empty, disables service registration -
+			// useful for local runs where nothing is discovering this
+			// instance. Set it to register with Consul so clients built
+			// with client/grpc.NewWithDiscovery or
+			// client/http.NewHTTPWithDiscovery can find it.
+			consulAddr = flag.String("consul-addr", "", "Consul agent address to register with (empty disables registration)")
+	)
+
+	flag.Parse()
+
+	/*
+		We then create a new context using the context.Background() function, which returns a
+		non-nil, empty context that has no cancelation or deadline specified and contains no 
+		values, perfect for the base context of all of our services. Requests and middleware 
+		are free to create new context objects from this one in order to add request-scoped 
+		data or deadlines
+	*/
+	ctx := context.Background()
+
+	/*
+		Logging and metrics wiring for the Go kit "microservice chassis"
+		pattern: every call to srv is logged and recorded, with its
+		duration, once it returns. requestCount/requestLatency are Prometheus-backed
+		and scraped from /metrics (see server_http.go).
+	*/
+	var logger kitlog.Logger
+	logger = kitlog.NewLogfmtLogger(os.Stderr)
+	logger = kitlog.With(logger, "ts", kitlog.DefaultTimestampUTC)
+
+	fieldKeys := []string{"method"}
+	requestCount := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "vault",
+		Subsystem: "vault_service",
+		Name:      "request_count",
+		Help:      "Number of requests received.",
+	}, fieldKeys)
+	requestLatency := kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+		Namespace: "vault",
+		Subsystem: "vault_service",
+		Name:      "request_latency_seconds",
+		Help:      "Total duration of requests in seconds.",
+	}, fieldKeys)
+
+	tracer := stdopentracing.GlobalTracer()
+
+	/*
+		keys supplies the pepper vaultService mixes into every password
+		before hashing. -key-provider=vault talks to a running Vault
+		cluster; env reads a single pepper out of the process environment;
+		none (the default) disables peppering, matching the service's
+		original unpeppered behavior.
+	*/
+	var keys keyprovider.KeyProvider
+	switch *keyProvider {
+	case "vault":
+		v, err := keyprovider.NewVault(keyprovider.VaultConfig{
+			Addr:    *vaultAddr,
+			Token:   *vaultToken,
+			KeyPath: *vaultKeyPath,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		keys = v
+	case "env":
+		keys = keyprovider.NewEnv(os.Getenv(*envPepperVar))
+	case "none":
+		keys = keyprovider.None{}
+	default:
+		log.Fatalf("unknown -key-provider %q (want vault, env, or none)", *keyProvider)
+	}
+
+	var srv vault.Service
+	srv = vault.NewServiceWithKeys(hasher.Default(), keys)
+	srv = middleware.LoggingMiddleware(logger)(srv)
+	srv = middleware.InstrumentingMiddleware(requestCount, requestLatency)(srv)
+
+	/*
+		reg registers this instance's HTTP and gRPC addresses with Consul so
+		client/grpc.NewWithDiscovery and client/http.NewHTTPWithDiscovery can
+		find it. Left nil (the default, -consul-addr unset) when there's
+		nothing to register with.
+	*/
+	var reg sd.Registrar
+	if *consulAddr != "" {
+		consulConfig := consulapi.DefaultConfig()
+		consulConfig.Address = *consulAddr
+		consulClient, err := consulapi.NewClient(consulConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		instanceID := fmt.Sprintf("%d", os.Getpid())
+		reg = registrar.NewConsul(consulsd.NewClient(consulClient), instanceID, *httpAddr, *gRPCAddr, logger)
+		reg.Register()
+	}
+
+	errChan := make(chan error)
+
+	/*
+		Buffered Channel
+
+		Untuk secara real time menerima kesalahan/error program
+	*/
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		if reg != nil {
+			reg.Deregister()
+		}
+		errChan <- fmt.Errorf("%s", sig)
+	}()
+
+
+	/*
+		Using Go Kit Endpoints
+	*/
+	hashEndpoint := vault.MakeHashEndpoint(srv)
+	hashEndpoint = middleware.TracingEndpointMiddleware(tracer, "Hash")(hashEndpoint)
+
+	validateEndpoint := vault.MakeValidateEndpoint(srv)
+	validateEndpoint = middleware.TracingEndpointMiddleware(tracer, "Validate")(validateEndpoint)
+
+	rotateEndpoint := vault.MakeRotateEndpoint(srv)
+	rotateEndpoint = middleware.TracingEndpointMiddleware(tracer, "Rotate")(rotateEndpoint)
+
+	endpoints := vault.Endpoints{
+									HashEndpoint: hashEndpoint,
+									ValidateEndpoint: validateEndpoint,
+									RotateEndpoint: rotateEndpoint,
+								}
+	/*
+		endpoint dengan rate limiter
+
+		hashEndpoint := vault.MakeHashEndpoint(srv){
+			hashEndpoint = ratelimitkit.NewTokenBucketLimiter(rlbucket)(hashEndpoint)
+		}
+
+		validateEndpoint := vault.MakeValidateEndpoint(srv){
+			validateEndpoint = ratelimitkit.NewTokenBucketLimiter(rlbucket)(validateEndpoint)
+		}	
+	
+	*/							
+
+	/*
+		Running HTTP Server
+	*/
+	go func() {
+
+		log.Println("http:", *httpAddr)
+		handler := vault.NewHTTPServer(ctx, endpoints)
+		errChan <- http.ListenAndServe(*httpAddr, handler)
+	}()
+
+
+	/*
+		Running gRPC Server
+	*/
+	go func() {
+		
+		listener, err := net.Listen("tcp", *gRPCAddr)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		log.Println("grpc:", *gRPCAddr)
+		gRPCServer := grpc.NewServer()
+		handler := vault.NewGRPCServer(ctx, endpoints, tracer, gRPCServer)
+		pb.RegisterVaultServer(gRPCServer, handler)
+
+		errChan <- gRPCServer.Serve(listener)
+	}()
+
+	log.Fatalln(<-errChan)
+}
+
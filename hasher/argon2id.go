@@ -0,0 +1,87 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func init() { Register(NewArgon2id(DefaultArgon2idParams)) }
+
+// Argon2idParams controls argon2.IDKey's cost.
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Time        uint32
+	Parallelism uint8
+	KeyLen      uint32
+}
+
+// DefaultArgon2idParams matches the OWASP password storage cheat sheet's
+// baseline recommendation (64 MiB, 3 iterations, 2 threads), and is the
+// params Default() hashes new passwords with.
+var DefaultArgon2idParams = Argon2idParams{Memory: 64 * 1024, Time: 3, Parallelism: 2, KeyLen: 32}
+
+// Argon2id hashes passwords with golang.org/x/crypto/argon2's Argon2id
+// variant, storing the argon2 version and cost parameters alongside a
+// random salt and the digest.
+type Argon2id struct {
+	params Argon2idParams
+}
+
+func NewArgon2id(params Argon2idParams) Argon2id {
+	return Argon2id{params: params}
+}
+
+func (a Argon2id) ID() string { return "argon2id" }
+
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Time, a.params.Memory, a.params.Parallelism, a.params.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.params.Memory, a.params.Time, a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a Argon2id) Validate(password, encoded string) (bool, error) {
+	// $argon2id$v=..$m=..,t=..,p=..$salt$digest
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, fmt.Errorf("hasher: malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, fmt.Errorf("hasher: malformed argon2id version: %w", err)
+	}
+
+	var memory, time uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &parallelism); err != nil {
+		return false, fmt.Errorf("hasher: malformed argon2id params: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, err
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, err
+	}
+
+	got := argon2.IDKey([]byte(password), salt, time, memory, parallelism, uint32(len(want)))
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
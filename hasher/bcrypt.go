@@ -0,0 +1,38 @@
+package hasher
+
+import "golang.org/x/crypto/bcrypt"
+
+func init() { Register(NewBcrypt(bcrypt.DefaultCost)) }
+
+// Bcrypt is the algorithm vaultService originally shipped with. Its hashes
+// are already self-describing ($2a$<cost>$<salt><digest>), so no extra
+// algorithm prefix is added on top.
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt builds a Bcrypt hasher that hashes new passwords at cost.
+// Validate doesn't need cost - bcrypt.CompareHashAndPassword reads it back
+// out of the encoded hash itself.
+func NewBcrypt(cost int) Bcrypt {
+	return Bcrypt{cost: cost}
+}
+
+func (b Bcrypt) ID() string { return "bcrypt" }
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (b Bcrypt) Validate(password, encoded string) (bool, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package hasher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultHasher is what Default() returns: the algorithm and parameters new
+// passwords are hashed with unless a caller explicitly asks for another one
+// (see hashRequest.Algorithm in vault/service.go). Raising this - or its
+// params - is how the service upgrades its baseline strength over time.
+var defaultHasher Hasher = NewArgon2id(DefaultArgon2idParams)
+
+// Default is the Hasher NewService configures Service.Hash to fall back to
+// when no algorithm is requested.
+func Default() Hasher {
+	return defaultHasher
+}
+
+// NeedsRehash reports whether encoded was produced by a weaker algorithm -
+// or weaker parameters of the same algorithm - than Default() currently
+// uses. validateResponse.NeedsRehash surfaces this so a caller can choose
+// to re-Hash and overwrite the stored value, mirroring how Vault-style
+// secret engines negotiate crypto parameters on read.
+func NeedsRehash(encoded string) bool {
+	id := AlgorithmOf(encoded)
+	if id != defaultHasher.ID() {
+		return true
+	}
+
+	argon, ok := defaultHasher.(Argon2id)
+	if !ok {
+		return false
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return true
+	}
+
+	var memory, time uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &parallelism); err != nil {
+		return true
+	}
+
+	return memory < argon.params.Memory || time < argon.params.Time || parallelism < argon.params.Parallelism
+}
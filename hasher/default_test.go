@@ -0,0 +1,39 @@
+package hasher
+
+import "testing"
+
+func TestNeedsRehashBcryptIsAlwaysWeakerThanDefault(t *testing.T) {
+	hash, err := NewBcrypt(bcryptTestCost).Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if !NeedsRehash(hash) {
+		t.Fatalf("NeedsRehash(%q) = false, want true: default is argon2id, not bcrypt", hash)
+	}
+}
+
+func TestNeedsRehashCurrentDefaultParams(t *testing.T) {
+	hash, err := Default().Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if NeedsRehash(hash) {
+		t.Fatalf("NeedsRehash(%q) = true, want false: hash was just produced with the current default params", hash)
+	}
+}
+
+func TestNeedsRehashWeakerArgon2idParams(t *testing.T) {
+	weak := Argon2idParams{Memory: 8 * 1024, Time: 1, Parallelism: 1, KeyLen: 32}
+	hash, err := NewArgon2id(weak).Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if !NeedsRehash(hash) {
+		t.Fatalf("NeedsRehash(%q) = false, want true: params are weaker than DefaultArgon2idParams", hash)
+	}
+}
+
+const bcryptTestCost = 4
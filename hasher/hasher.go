@@ -0,0 +1,69 @@
+// Package hasher decouples the Vault service from any single password
+// hashing algorithm. Every implementation encodes its algorithm (and, where
+// relevant, its cost parameters) as a prefix on the hash it returns -
+// $<id>$<params>$<digest>, in the style of the traditional modular crypt
+// format - so Validate can read the prefix back off a stored hash and
+// dispatch to whichever Hasher produced it, even if that's no longer the
+// service's default.
+package hasher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hasher hashes and validates passwords for a single algorithm.
+type Hasher interface {
+	// Hash returns an encoded hash (including its algorithm prefix) for
+	// password.
+	Hash(password string) (string, error)
+
+	// Validate reports whether password matches encoded. encoded must
+	// have been produced by a Hasher with this ID - callers normally get
+	// there via ByEncoded rather than calling Validate directly.
+	Validate(password, encoded string) (bool, error)
+
+	// ID is the algorithm name stored as the hash's prefix, e.g.
+	// "bcrypt", "scrypt", "argon2id".
+	ID() string
+}
+
+// registry holds every Hasher this build knows how to verify, keyed by ID.
+// Each implementation registers itself from its own init().
+var registry = map[string]Hasher{}
+
+// Register makes h available to Lookup/ByEncoded under h.ID(). It panics on
+// a duplicate ID, which would otherwise silently shadow an algorithm.
+func Register(h Hasher) {
+	if _, exists := registry[h.ID()]; exists {
+		panic(fmt.Sprintf("hasher: algorithm %q already registered", h.ID()))
+	}
+	registry[h.ID()] = h
+}
+
+// Lookup returns the Hasher registered under id, if any.
+func Lookup(id string) (Hasher, bool) {
+	h, ok := registry[id]
+	return h, ok
+}
+
+// AlgorithmOf extracts the algorithm id from an encoded hash. bcrypt
+// predates this package's prefix convention and is self-describing via its
+// own "$2a$"/"$2b$"/"$2y$" prefix, so it's special-cased rather than
+// re-encoded.
+func AlgorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return "bcrypt"
+	case strings.HasPrefix(encoded, "$"):
+		return strings.SplitN(encoded[1:], "$", 2)[0]
+	default:
+		return ""
+	}
+}
+
+// ByEncoded returns the Hasher that can verify encoded, based on its
+// algorithm prefix.
+func ByEncoded(encoded string) (Hasher, bool) {
+	return Lookup(AlgorithmOf(encoded))
+}
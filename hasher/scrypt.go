@@ -0,0 +1,82 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+func init() { Register(NewScrypt(DefaultScryptParams)) }
+
+// ScryptParams controls the CPU/memory cost of scrypt.Key.
+type ScryptParams struct {
+	N, R, P, KeyLen int
+}
+
+// DefaultScryptParams follows the interactive-login parameters scrypt's
+// author recommends (N=2^15, r=8, p=1).
+var DefaultScryptParams = ScryptParams{N: 32768, R: 8, P: 1, KeyLen: 32}
+
+// Scrypt hashes passwords with golang.org/x/crypto/scrypt, storing its cost
+// parameters and a random salt alongside the digest.
+type Scrypt struct {
+	params ScryptParams
+}
+
+func NewScrypt(params ScryptParams) Scrypt {
+	return Scrypt{params: params}
+}
+
+func (s Scrypt) ID() string { return "scrypt" }
+
+func (s Scrypt) Hash(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := scrypt.Key([]byte(password), salt, s.params.N, s.params.R, s.params.P, s.params.KeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$scrypt$n=%d,r=%d,p=%d$%s$%s",
+		s.params.N, s.params.R, s.params.P,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (s Scrypt) Validate(password, encoded string) (bool, error) {
+	// $scrypt$n=..,r=..,p=..$salt$digest
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return false, fmt.Errorf("hasher: malformed scrypt hash")
+	}
+
+	var n, r, p int
+	if _, err := fmt.Sscanf(parts[2], "n=%d,r=%d,p=%d", &n, &r, &p); err != nil {
+		return false, fmt.Errorf("hasher: malformed scrypt params: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, err
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, err
+	}
+
+	got, err := scrypt.Key([]byte(password), salt, n, r, p, len(want))
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
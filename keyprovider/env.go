@@ -0,0 +1,33 @@
+package keyprovider
+
+import "golang.org/x/net/context"
+
+// Env sources a single, unversioned pepper from a pre-read environment
+// variable - a low-ceremony alternative to Vault for development, or for
+// deployments that don't run a Vault cluster. Because there's only ever
+// one version, it can never verify a hash minted under a different key;
+// after the pepper changes, neither Validate nor Rotate accepts a hash
+// peppered with the old value.
+type Env struct {
+	key []byte
+}
+
+// NewEnv builds an Env provider from key, the literal pepper value (e.g.
+// read by main.go from the VAULT_PEPPER environment variable).
+func NewEnv(key string) Env {
+	return Env{key: []byte(key)}
+}
+
+func (e Env) Current(ctx context.Context) (int, []byte, error) {
+	if len(e.key) == 0 {
+		return 0, nil, nil
+	}
+	return 1, e.key, nil
+}
+
+func (e Env) Version(ctx context.Context, version int) ([]byte, error) {
+	if version != 1 {
+		return nil, nil
+	}
+	return e.key, nil
+}
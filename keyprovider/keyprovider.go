@@ -0,0 +1,20 @@
+// Package keyprovider supplies the HMAC pepper the Vault service mixes
+// into every password before it reaches a hasher.Hasher, and lets a
+// caller look up a specific historical version of that pepper so hashes
+// minted before a rotation keep verifying afterwards.
+package keyprovider
+
+import "golang.org/x/net/context"
+
+// KeyProvider is the pepper source vaultService is configured with (flag
+// -key-provider in cmd/vaultd/main.go).
+type KeyProvider interface {
+	// Current returns the pepper's current version and key material. A
+	// version of 0 with a nil key means peppering is disabled.
+	Current(ctx context.Context) (version int, key []byte, err error)
+
+	// Version returns the key material for a specific historical
+	// version, so legacy hashes can still be verified after a rotation.
+	// Looking up version 0 always returns a nil key.
+	Version(ctx context.Context, version int) (key []byte, err error)
+}
@@ -0,0 +1,178 @@
+package keyprovider
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+	"golang.org/x/net/context"
+)
+
+// VaultConfig configures a Vault provider.
+type VaultConfig struct {
+	Addr    string // -vault-addr
+	Token   string // -vault-token
+	KeyPath string // -vault-key-path, a KV v2 secret path holding a "pepper" field
+}
+
+// Vault sources the HMAC pepper from HashiCorp Vault's KV v2 secret
+// engine. Keys are cached in memory by KV version once read, so Validate
+// can verify a hash minted under an older pepper without re-reading Vault
+// on every call, and the client's token lease is kept alive in the
+// background with an api.Renewer.
+type Vault struct {
+	client  *api.Client
+	keyPath string
+
+	mu       sync.RWMutex
+	versions map[int][]byte
+	current  int
+}
+
+// NewVault builds a Vault provider, performs an initial read of keyPath so
+// construction fails fast on misconfiguration, and starts the background
+// token renewal loop.
+func NewVault(cfg VaultConfig) (*Vault, error) {
+	clientCfg := api.DefaultConfig()
+	clientCfg.Address = cfg.Addr
+
+	client, err := api.NewClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("keyprovider: new vault client: %w", err)
+	}
+	client.SetToken(cfg.Token)
+
+	v := &Vault{
+		client:   client,
+		keyPath:  cfg.KeyPath,
+		versions: make(map[int][]byte),
+	}
+
+	if err := v.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+
+	go v.renewSelf()
+
+	return v, nil
+}
+
+// refresh reads the current version of the pepper and caches it.
+func (v *Vault) refresh(ctx context.Context) error {
+	secret, err := v.client.Logical().ReadWithContext(ctx, v.keyPath)
+	if err != nil {
+		return fmt.Errorf("keyprovider: read %s: %w", v.keyPath, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return fmt.Errorf("keyprovider: no secret at %s", v.keyPath)
+	}
+
+	version := 1
+	if meta, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		if f, ok := meta["version"].(float64); ok {
+			version = int(f)
+		}
+	}
+
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("keyprovider: %s has no data", v.keyPath)
+	}
+
+	pepper, ok := data["pepper"].(string)
+	if !ok {
+		return fmt.Errorf("keyprovider: %s has no \"pepper\" field", v.keyPath)
+	}
+
+	v.mu.Lock()
+	v.versions[version] = []byte(pepper)
+	v.current = version
+	v.mu.Unlock()
+
+	return nil
+}
+
+// renewSelf keeps the client's own token alive for the life of the
+// process using an api.Renewer; if the token can't be renewed (it's
+// non-renewable, or Vault is unreachable) it simply stops, leaving the
+// operator to rotate the token before it expires.
+func (v *Vault) renewSelf() {
+	secret, err := v.client.Auth().Token().RenewSelf(0)
+	if err != nil {
+		return
+	}
+
+	renewer, err := v.client.NewRenewer(&api.RenewerInput{Secret: secret})
+	if err != nil {
+		return
+	}
+
+	go renewer.Renew()
+	defer renewer.Stop()
+
+	for range renewer.RenewCh() {
+		// lease renewed; nothing else to do until the next tick or error
+	}
+}
+
+// Current returns the cached current pepper version, populated by the
+// refresh NewVault does at construction time. It deliberately does not hit
+// Vault on every call - Hash calls Current once per request, and a live
+// round-trip there would put Vault's availability and latency directly on
+// the hot path. A rotated pepper becomes the new "current" version the
+// next time this process restarts (and so re-runs NewVault); Validate and
+// Rotate are unaffected by this, since they look up whichever version a
+// stored hash actually names via Version, not Current.
+func (v *Vault) Current(ctx context.Context) (int, []byte, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	key, ok := v.versions[v.current]
+	if !ok {
+		return 0, nil, fmt.Errorf("keyprovider: no pepper cached for %s", v.keyPath)
+	}
+
+	return v.current, key, nil
+}
+
+func (v *Vault) Version(ctx context.Context, version int) ([]byte, error) {
+	if version == 0 {
+		return nil, nil
+	}
+
+	v.mu.RLock()
+	key, ok := v.versions[version]
+	v.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	secret, err := v.client.Logical().ReadWithDataWithContext(ctx, v.keyPath, map[string][]string{
+		"version": {strconv.Itoa(version)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("keyprovider: read %s version %d: %w", v.keyPath, version, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("keyprovider: no secret at %s version %d", v.keyPath, version)
+	}
+
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("keyprovider: %s version %d has no data", v.keyPath, version)
+	}
+
+	pepper, ok := data["pepper"].(string)
+	if !ok {
+		return nil, fmt.Errorf("keyprovider: %s version %d has no \"pepper\" field", v.keyPath, version)
+	}
+
+	key = []byte(pepper)
+
+	v.mu.Lock()
+	v.versions[version] = key
+	v.mu.Unlock()
+
+	return key, nil
+}
@@ -0,0 +1,120 @@
+// Package middleware provides the cross-cutting decorators of the Go kit
+// "microservice chassis" pattern for Vault: service-level logging and
+// instrumenting middlewares, plus (in tracing.go) the gRPC client tracing
+// option and the endpoint tracing middleware. None of these touch
+// the hashing/validation logic itself - they only wrap a vault.Service (or
+// a transport handler) with an extra concern.
+package middleware
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/net/context"
+
+	vault "Microservice/Vault"
+)
+
+// ServiceMiddleware is a chainable behavior decorator for vault.Service, the
+// same shape used throughout Go kit services: main wires these up with
+// `srv = LoggingMiddleware(logger)(srv)`.
+type ServiceMiddleware func(vault.Service) vault.Service
+
+// LoggingMiddleware logs the method name, duration, and error (if any) of
+// every Hash/Validate/Rotate call.
+func LoggingMiddleware(logger log.Logger) ServiceMiddleware {
+	return func(next vault.Service) vault.Service {
+		return loggingMiddleware{logger, next}
+	}
+}
+
+type loggingMiddleware struct {
+	logger log.Logger
+	next   vault.Service
+}
+
+func (mw loggingMiddleware) Hash(ctx context.Context, password, algorithm string) (hash string, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Hash",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+
+	return mw.next.Hash(ctx, password, algorithm)
+}
+
+func (mw loggingMiddleware) Validate(ctx context.Context, password, hash string) (valid bool, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Validate",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+
+	return mw.next.Validate(ctx, password, hash)
+}
+
+func (mw loggingMiddleware) Rotate(ctx context.Context, password, oldHash string) (hash string, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Rotate",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+
+	return mw.next.Rotate(ctx, password, oldHash)
+}
+
+// InstrumentingMiddleware records a per-method request count and a request
+// latency histogram, wired to Prometheus (via go-kit/kit/metrics/prometheus)
+// in cmd/vaultd/main.go and exposed on /metrics.
+func InstrumentingMiddleware(requestCount metrics.Counter, requestLatency metrics.Histogram) ServiceMiddleware {
+	return func(next vault.Service) vault.Service {
+		return instrumentingMiddleware{
+			requestCount:   requestCount,
+			requestLatency: requestLatency,
+			next:           next,
+		}
+	}
+}
+
+type instrumentingMiddleware struct {
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+	next           vault.Service
+}
+
+func (mw instrumentingMiddleware) Hash(ctx context.Context, password, algorithm string) (string, error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "Hash"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mw.next.Hash(ctx, password, algorithm)
+}
+
+func (mw instrumentingMiddleware) Validate(ctx context.Context, password, hash string) (bool, error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "Validate"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mw.next.Validate(ctx, password, hash)
+}
+
+func (mw instrumentingMiddleware) Rotate(ctx context.Context, password, oldHash string) (string, error) {
+	defer func(begin time.Time) {
+		lvs := []string{"method", "Rotate"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mw.next.Rotate(ctx, password, oldHash)
+}
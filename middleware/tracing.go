@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	kitot "github.com/go-kit/kit/tracing/opentracing"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+)
+
+// TracingClientOption is the client-side counterpart to the gRPC server's
+// gRPCTracingServerOption (server_grpc.go): it injects the span carried on
+// ctx into the outbound gRPC metadata so the Vault instance that receives
+// the call can continue the same trace.
+func TracingClientOption(tracer stdopentracing.Tracer, operationName string) grpctransport.ClientOption {
+	return grpctransport.ClientBefore(kitot.ContextToGRPC(tracer, log.NewNopLogger()))
+}
+
+// TracingEndpointMiddleware is the endpoint.Middleware counterpart to
+// TracingClientOption: where that injects the span into the
+// gRPC wire, this one actually opens the span for a single endpoint
+// invocation, wrapping MakeHashEndpoint/MakeValidateEndpoint/
+// MakeRotateEndpoint the same way LoggingMiddleware and
+// InstrumentingMiddleware wrap the whole Service:
+//
+//	hashEndpoint = middleware.TracingEndpointMiddleware(tracer, "Hash")(hashEndpoint)
+func TracingEndpointMiddleware(tracer stdopentracing.Tracer, operationName string) endpoint.Middleware {
+	return kitot.TraceServer(tracer, operationName)
+}
@@ -0,0 +1,64 @@
+package vault
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+	Peppering mixes a server-side secret (the "pepper", sourced from a
+	keyprovider.KeyProvider) into a password before it ever reaches a
+	Hasher. Unlike a hash's per-password salt, the pepper is never stored
+	alongside the hash - a leaked password database alone isn't enough to
+	brute-force it, the attacker also needs the pepper key.
+*/
+
+// pepperPassword HMAC-SHA256s password with key and hex-encodes the
+// result. A nil/empty key (keyprovider.None) is a no-op, so hashes written
+// before peppering was introduced keep validating unchanged.
+func pepperPassword(key []byte, password string) string {
+	if len(key) == 0 {
+		return password
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(password))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// encodeVersioned prefixes an already hasher-encoded hash with the pepper
+// key version it was peppered with, e.g. "v2$$argon2id$v=19$..." (the
+// hasher's own encoding keeps its leading "$").
+func encodeVersioned(version int, hash string) string {
+	if version == 0 {
+		return hash
+	}
+
+	return fmt.Sprintf("v%d$%s", version, hash)
+}
+
+// decodeVersioned splits a versioned hash back into its key version and
+// the hasher-encoded tail. Hashes written before peppering was introduced
+// carry no "vN$" prefix at all, and decode to version 0 (no pepper).
+func decodeVersioned(encoded string) (version int, hash string) {
+	if !strings.HasPrefix(encoded, "v") {
+		return 0, encoded
+	}
+
+	parts := strings.SplitN(encoded[1:], "$", 2)
+	if len(parts) != 2 {
+		return 0, encoded
+	}
+
+	v, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, encoded
+	}
+
+	return v, parts[1]
+}
@@ -0,0 +1,49 @@
+package vault
+
+import "testing"
+
+func TestEncodeDecodeVersionedRoundTrip(t *testing.T) {
+	cases := []struct {
+		version int
+		hash    string
+	}{
+		{0, "$argon2id$v=19$m=65536,t=3,p=2$salt$digest"},
+		{1, "$argon2id$v=19$m=65536,t=3,p=2$salt$digest"},
+		{42, "$2a$10$abcdefghijklmnopqrstuv"},
+	}
+
+	for _, c := range cases {
+		encoded := encodeVersioned(c.version, c.hash)
+
+		gotVersion, gotHash := decodeVersioned(encoded)
+		if gotVersion != c.version || gotHash != c.hash {
+			t.Errorf("decodeVersioned(encodeVersioned(%d, %q)) = (%d, %q), want (%d, %q)",
+				c.version, c.hash, gotVersion, gotHash, c.version, c.hash)
+		}
+	}
+}
+
+func TestDecodeVersionedUnversionedLegacyHash(t *testing.T) {
+	legacy := "$2a$10$abcdefghijklmnopqrstuv"
+
+	version, hash := decodeVersioned(legacy)
+	if version != 0 || hash != legacy {
+		t.Errorf("decodeVersioned(%q) = (%d, %q), want (0, %q): a hash written before peppering has no vN$ prefix",
+			legacy, version, hash, legacy)
+	}
+}
+
+func TestPepperPasswordNoKeyIsNoOp(t *testing.T) {
+	if got := pepperPassword(nil, "hunter2"); got != "hunter2" {
+		t.Errorf("pepperPassword(nil, %q) = %q, want unchanged password", "hunter2", got)
+	}
+}
+
+func TestPepperPasswordChangesWithKey(t *testing.T) {
+	plain := pepperPassword(nil, "hunter2")
+	peppered := pepperPassword([]byte("pepper-key"), "hunter2")
+
+	if plain == peppered {
+		t.Errorf("pepperPassword with a key produced the same output as no key at all")
+	}
+}
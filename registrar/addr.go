@@ -0,0 +1,35 @@
+package registrar
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+// addrHost and addrPort split a "host:port" listen address (as taken
+// straight from the -http/-grpc flags in cmd/vaultd) into the separate
+// Address/Port fields Consul's AgentServiceRegistration wants. addrHost
+// falls back to the local hostname when addr has no host part (e.g. the
+// default ":8080"), since Consul can't register an empty address.
+func addrHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			return hostname
+		}
+		return "localhost"
+	}
+	return host
+}
+
+func addrPort(addr string) int {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return 0
+	}
+	return p
+}
@@ -0,0 +1,53 @@
+// Package registrar registers this process's own HTTP and gRPC listen
+// addresses with a service discovery backend, so the client-side
+// sd.Instancer used by client/grpc.NewWithDiscovery and
+// client/http.NewHTTPWithDiscovery can find it. Today it only knows how to
+// talk to Consul; adding etcd or another backend means adding a
+// constructor alongside NewConsul, not changing main.go.
+package registrar
+
+import (
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/sd"
+	consulsd "github.com/go-kit/kit/sd/consul"
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// multiRegistrar composes several sd.Registrar into one, so main.go can
+// Register/Deregister the HTTP and gRPC listeners with a single call at
+// startup and a single call on SIGTERM.
+type multiRegistrar []sd.Registrar
+
+func (r multiRegistrar) Register() {
+	for _, registrar := range r {
+		registrar.Register()
+	}
+}
+
+func (r multiRegistrar) Deregister() {
+	for _, registrar := range r {
+		registrar.Deregister()
+	}
+}
+
+// NewConsul registers instanceID twice with the Consul agent behind client -
+// once as "vault-http" at httpAddr, once as "vault-grpc" at grpcAddr - and
+// returns a single sd.Registrar whose Register/Deregister drive both.
+func NewConsul(client consulsd.Client, instanceID, httpAddr, grpcAddr string, logger log.Logger) sd.Registrar {
+
+	return multiRegistrar{
+		consulsd.NewRegistrar(client, &consulapi.AgentServiceRegistration{
+			ID:      "vault-http-" + instanceID,
+			Name:    "vault-http",
+			Address: addrHost(httpAddr),
+			Port:    addrPort(httpAddr),
+		}, log.With(logger, "registrar", "vault-http")),
+
+		consulsd.NewRegistrar(client, &consulapi.AgentServiceRegistration{
+			ID:      "vault-grpc-" + instanceID,
+			Name:    "vault-grpc",
+			Address: addrHost(grpcAddr),
+			Port:    addrPort(grpcAddr),
+		}, log.With(logger, "registrar", "vault-grpc")),
+	}
+}
@@ -1,13 +1,24 @@
-package Vault
+package vault
 
 import (
+	"golang.org/x/crypto/bcrypt"
+
+	kitot "github.com/go-kit/kit/tracing/opentracing"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"Microservice/Vault/pb"
 )
 
 type grpcServer struct {
 	hash     grpctransport.Handler
 	validate grpctransport.Handler
+	rotate   grpctransport.Handler
 }
 
 /*
@@ -38,6 +49,16 @@ func (s *grpcServer) Validate(ctx context.Context, r *pb.ValidateRequest) (*pb.V
 	return resp.(*pb.ValidateResponse), nil
 }
 
+func (s *grpcServer) Rotate(ctx context.Context, r *pb.RotateRequest) (*pb.RotateResponse, error) {
+
+	_, resp, err := s.rotate.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RotateResponse), nil
+}
+
 /*
 	HashRequest objek pada PB
 	hashRequest objek pada service.go
@@ -49,7 +70,7 @@ func EncodeGRPCHashRequest(ctx context.Context, r interface{}) (interface{}, err
 
 	req := r.(hashRequest)
 
-	return &pb.HashRequest{Password: req.Password}, nil
+	return &pb.HashRequest{Password: req.Password, Algorithm: req.Algorithm}, nil
 }
 
 /*
@@ -60,7 +81,7 @@ func DecodeGRPCHashRequest(ctx context.Context, r interface{}) (interface{}, err
 
 	req := r.(*pb.HashRequest)
 
-	return hashRequest{Password: req.Password}, nil
+	return hashRequest{Password: req.Password, Algorithm: req.Algorithm}, nil
 }
 
 func EncodeGRPCHashResponse(ctx context.Context, r interface{}) (interface{}, error) {
@@ -95,14 +116,42 @@ func EncodeGRPCValidateResponse(ctx context.Context, r interface{}) (interface{}
 
 	res := r.(validateResponse)
 
-	return &pb.ValidateResponse{Valid: res.Valid}, nil
+	return &pb.ValidateResponse{Valid: res.Valid, NeedsRehash: res.NeedsRehash}, nil
 }
 
 func DecodeGRPCValidateResponse(ctx context.Context, r interface{}) (interface{}, error) {
 
 	res := r.(*pb.ValidateResponse)
 
-	return validateResponse{Valid: res.Valid}, nil
+	return validateResponse{Valid: res.Valid, NeedsRehash: res.NeedsRehash}, nil
+}
+
+func EncodeGRPCRotateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+
+	req := r.(rotateRequest)
+
+	return &pb.RotateRequest{Password: req.Password, OldHash: req.OldHash}, nil
+}
+
+func DecodeGRPCRotateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+
+	req := r.(*pb.RotateRequest)
+
+	return rotateRequest{Password: req.Password, OldHash: req.OldHash}, nil
+}
+
+func EncodeGRPCRotateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+
+	res := r.(rotateResponse)
+
+	return &pb.RotateResponse{Hash: res.Hash, Err: res.Err}, nil
+}
+
+func DecodeGRPCRotateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+
+	res := r.(*pb.RotateResponse)
+
+	return rotateResponse{Hash: res.Hash, Err: res.Err}, nil
 }
 
 /*
@@ -110,19 +159,80 @@ func DecodeGRPCValidateResponse(ctx context.Context, r interface{}) (interface{}
 	gRPC server. We create and return a new instance of our grpcServer type, setting the handlers for both hash and validate
 	by callinggrpctransport.NewServer. We use our endpoint.Endpoint functions for our service and tell the service which of
 	our encoding/decoding functions to use for each case.
+
+	tracer is threaded through so every call carries its own span
+	(gRPCTracingServerOption), letting a single Hash/Validate request be
+	followed across the HTTP edge and into this gRPC server. This is kept
+	local rather than going through the middleware package, since that
+	package already imports this one (for vault.Service) and the reverse
+	import would be a cycle.
+
+	NewGRPCServer also registers reflection and a grpc_health_v1 health
+	server on server, so the same process can be probed with grpcurl or a
+	Kubernetes gRPC liveness probe without a separate listener. Health is
+	reported SERVING once a bcrypt self-test succeeds - a bcrypt failure
+	means the process can't do its one job, so it should fail readiness
+	rather than accept traffic it can't serve.
 */
-func NewGRPCServer(ctx context.Context, endpoints Endpoints) pb.VaultServer {
+func NewGRPCServer(ctx context.Context, endpoints Endpoints, tracer stdopentracing.Tracer, server *grpc.Server) pb.VaultServer {
 
-	return &grpcServer{
+	srv := &grpcServer{
 
 		hash: grpctransport.NewServer(ctx,
 			endpoints.HashEndpoint,
 			DecodeGRPCHashRequest,
-			EncodeGRPCHashResponse),
+			EncodeGRPCHashResponse,
+			gRPCTracingServerOption(tracer, "Hash")),
 
 		validate: grpctransport.NewServer(ctx,
 			endpoints.ValidateEndpoint,
 			DecodeGRPCValidateRequest,
-			EncodeGRPCValidateResponse),
+			EncodeGRPCValidateResponse,
+			gRPCTracingServerOption(tracer, "Validate")),
+
+		rotate: grpctransport.NewServer(ctx,
+			endpoints.RotateEndpoint,
+			DecodeGRPCRotateRequest,
+			EncodeGRPCRotateResponse,
+			gRPCTracingServerOption(tracer, "Rotate")),
 	}
+
+	reflection.Register(server)
+
+	healthServer := health.NewServer()
+	status := bcryptSelfTestStatus()
+	// "" is the overall server status grpc_health_probe, grpcurl, and a
+	// Kubernetes gRPC liveness probe check by default - set it alongside
+	// pb.ServiceName so those tools work without being told which service
+	// name to ask for.
+	healthServer.SetServingStatus("", status)
+	healthServer.SetServingStatus(pb.ServiceName, status)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	return srv
+}
+
+// gRPCTracingServerOption returns a grpctransport.ServerOption that starts
+// (or joins, if the caller sent one) an OpenTracing span named
+// operationName for every inbound call - the server-side counterpart to
+// middleware.TracingClientOption.
+func gRPCTracingServerOption(tracer stdopentracing.Tracer, operationName string) grpctransport.ServerOption {
+	return grpctransport.ServerBefore(kitot.GRPCToContext(tracer, operationName, nil))
+}
+
+// bcryptSelfTestStatus hashes and validates a throwaway password to make
+// sure bcrypt itself is working before the health server reports SERVING -
+// if that fails, nothing this service does will work, so it should fail
+// readiness rather than accept traffic it can't serve.
+func bcryptSelfTestStatus() healthpb.HealthCheckResponse_ServingStatus {
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("vault-self-test"), bcrypt.DefaultCost)
+	if err != nil {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte("vault-self-test")); err != nil {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
 }
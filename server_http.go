@@ -1,13 +1,21 @@
-package Vault
+package vault
 
 import (
 	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/net/context"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"net/http"
 )
 
 /*
 	An HTTP Server in Go Kit
+
+	/metrics is mounted alongside the JSON API so Prometheus can scrape the
+	counters and histograms recorded by middleware.InstrumentingMiddleware
+	without needing a second listener. /healthz and /readyz are mounted the
+	same way for a load balancer or Kubernetes HTTP probe - the gRPC
+	listener has the equivalent grpc_health_v1 server, see NewGRPCServer.
 */
 
 func NewHTTPServer(ctx context.Context, endpoints Endpoints) http.Handler {
@@ -15,6 +23,33 @@ func NewHTTPServer(ctx context.Context, endpoints Endpoints) http.Handler {
 	m := http.NewServeMux()
 	m.Handle("/hash", httptransport.NewServer(ctx, endpoints.HashEndpoint, decodeHashRequest, encodeResponse))
 	m.Handle("/validate", httptransport.NewServer(ctx, endpoints.ValidateEndpoint, decodeValidateRequest, encodeResponse))
+	m.Handle("/rotate", httptransport.NewServer(ctx, endpoints.RotateEndpoint, decodeRotateRequest, encodeResponse))
+	m.Handle("/metrics", promhttp.Handler())
+	m.HandleFunc("/healthz", handleHealthz)
+	m.HandleFunc("/readyz", handleReadyz)
 
 	return m
 }
+
+// handleHealthz reports liveness: the process is up and able to answer
+// HTTP requests at all. It never fails on its own - a dead process can't
+// serve this either way - so it's safe to keep simple.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports readiness: whether the process can actually do its
+// one job right now. Like the gRPC health server's bcryptSelfTestStatus,
+// it hashes and validates a throwaway password - a bcrypt failure means
+// this instance should be taken out of rotation even though it's alive.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if status := bcryptSelfTestStatus(); status != healthpb.HealthCheckResponse_SERVING {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("not ready"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ready"))
+}
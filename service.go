@@ -1,53 +1,132 @@
-package Microservice
+package vault
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/net/context"
+
+	"Microservice/Vault/hasher"
+	"Microservice/Vault/keyprovider"
+)
 
 // Service provides password hashing capabilities.
 type Service interface {
 
-	Hash(ctx context.Context, password string) (string, error)
+	Hash(ctx context.Context, password, algorithm string) (string, error)
 	Validate(ctx context.Context, password, hash string) (bool, error)
 
+	// Rotate verifies password against oldHash and, if it matches,
+	// re-hashes password with the service's current pepper key version
+	// (and Hasher), so a stored hash can be brought current after a key
+	// rotation without waiting for the user's next successful Validate.
+	Rotate(ctx context.Context, password, oldHash string) (string, error)
+
 }
 
 /*
-	Part of designing micro-services is being careful about where state is stored. Even though you will implement the methods 
-	of a service in a single file, with access to global variables, you should never use them to store the per-request or even 
+	Part of designing micro-services is being careful about where state is stored. Even though you will implement the methods
+	of a service in a single file, with access to global variables, you should never use them to store the per-request or even
 	per-service state. It's important to remember that each service is likely to be running on many physical machines multiple
 	times, each with no access to the others' global variables.
+
+	vaultService now carries two pieces of config - which Hasher it hashes
+	new passwords with, and which KeyProvider it peppers them with - set
+	once at construction and never mutated afterwards, so it's still safe
+	to run on many machines with no shared state between them.
 */
-type vaultService struct{}
+type vaultService struct {
+	hasher hasher.Hasher
+	keys   keyprovider.KeyProvider
+}
 
-// NewService makes a new Service.
+// NewService makes a new Service that hashes new passwords with
+// hasher.Default() and does not pepper them (keyprovider.None). Use
+// NewServiceWithKeys to configure peppering.
 func NewService() Service {
-	return vaultService{}
+	return NewServiceWithKeys(hasher.Default(), keyprovider.None{})
 }
 
-// Ensure that you import the appropriate bcrypt package (try golang.org/x/crypto/bcrypt)
+// NewServiceWithHasher makes a new Service that hashes new passwords with h
+// instead of hasher.Default(), without peppering. Validate is unaffected by
+// h - it always dispatches to whichever Hasher's prefix the stored hash
+// carries, via hasher.ByEncoded, so a service can be pointed at a new
+// algorithm without breaking validation of hashes written by the old one.
+func NewServiceWithHasher(h hasher.Hasher) Service {
+	return NewServiceWithKeys(h, keyprovider.None{})
+}
 
-/*
-	Note that the receiver in the Hash method is just (vaultService); we don't capture the variable because there is no way 
-	we can store state on an empty struct
-*/
-func (vaultService) Hash(ctx context.Context, password string) (string, error) {
+// NewServiceWithKeys makes a new Service that hashes new passwords with h,
+// peppered with keys' current key. keys is also consulted by Validate and
+// Rotate to locate the pepper version a stored hash was peppered with, so
+// keys can rotate without invalidating hashes written under an older
+// version - see pepper.go.
+func NewServiceWithKeys(h hasher.Hasher, keys keyprovider.KeyProvider) Service {
+	return vaultService{hasher: h, keys: keys}
+}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// Hash hashes password with algorithm if given, or with the service's
+// configured Hasher if algorithm is empty, after peppering it with the key
+// provider's current version. An unrecognized algorithm is an error.
+func (v vaultService) Hash(ctx context.Context, password, algorithm string) (string, error) {
 
+	h := v.hasher
+	if algorithm != "" {
+		requested, ok := hasher.Lookup(algorithm)
+		if !ok {
+			return "", fmt.Errorf("vault: unknown hash algorithm %q", algorithm)
+		}
+		h = requested
+	}
+
+	version, key, err := v.keys.Current(ctx)
+	if err != nil {
+		return "", fmt.Errorf("vault: fetching pepper: %w", err)
+	}
+
+	hash, err := h.Hash(pepperPassword(key, password))
 	if err != nil {
 		return "", err
 	}
 
-	return string(hash), nil
+	return encodeVersioned(version, hash), nil
 }
 
-func (vaultService) Validate(ctx context.Context, password, hash string) (bool, error) {
+func (v vaultService) Validate(ctx context.Context, password, encoded string) (bool, error) {
 
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	version, hash := decodeVersioned(encoded)
+
+	var key []byte
+	if version != 0 {
+		k, err := v.keys.Version(ctx, version)
+		if err != nil {
+			return false, fmt.Errorf("vault: fetching pepper version %d: %w", version, err)
+		}
+		key = k
+	}
 
+	h, ok := hasher.ByEncoded(hash)
+	if !ok {
+		return false, fmt.Errorf("vault: unrecognized hash algorithm for %q", hasher.AlgorithmOf(hash))
+	}
+
+	return h.Validate(pepperPassword(key, password), hash)
+}
+
+func (v vaultService) Rotate(ctx context.Context, password, oldHash string) (string, error) {
+
+	valid, err := v.Validate(ctx, password, oldHash)
 	if err != nil {
-		return false, nil
+		return "", err
+	}
+	if !valid {
+		return "", errors.New("vault: password does not match oldHash")
 	}
 
-	return true, nil
+	return v.Hash(ctx, password, "")
 }
 
 
@@ -60,6 +139,10 @@ func (vaultService) Validate(ctx context.Context, password, hash string) (bool,
 
 type hashRequest struct {
 	Password string `json:"password"`
+	// Algorithm optionally names a registered hasher.Hasher (e.g.
+	// "bcrypt", "scrypt", "argon2id"). Left empty, the service's
+	// configured default is used.
+	Algorithm string `json:"algorithm,omitempty"`
 }
 
 type hashResponse struct {
@@ -76,8 +159,7 @@ func decodeHashRequest(ctx context.Context, r *http.Request) (interface{}, error
 
 	var req hashRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
-i
-	f err != nil {
+	if err != nil {
 		return nil, err
 	}
 
@@ -93,13 +175,40 @@ type validateRequest struct {
 type validateResponse struct {
 	Valid bool `json:"valid"`
 	Err string `json:"err,omitempty"`
+	// NeedsRehash is set when Valid is true but the stored hash was
+	// produced by a weaker algorithm, or weaker parameters, than
+	// hasher.Default() currently uses - see hasher.NeedsRehash.
+	NeedsRehash bool `json:"needs_rehash,omitempty"`
 }
 
 func decodeValidateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	
+
 	var req validateRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
-	
+
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+
+type rotateRequest struct {
+	Password string `json:"password"`
+	OldHash  string `json:"old_hash"`
+}
+
+type rotateResponse struct {
+	Hash string `json:"hash"`
+	Err  string `json:"err,omitempty"`
+}
+
+func decodeRotateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+
+	var req rotateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+
 	if err != nil {
 		return nil, err
 	}
@@ -129,12 +238,12 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	If all is well, we build hashResponse with the value we got back from the Hash method and return it
 */
 
-func MakeHashEndpoint(srv Service) endpoint.Endpoint { 
+func MakeHashEndpoint(srv Service) endpoint.Endpoint {
 
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 
 		req := request.(hashRequest)
-		v, err := srv.Hash(ctx, req.Password)
+		v, err := srv.Hash(ctx, req.Password, req.Algorithm)
 
 		if err != nil {
 			return hashResponse{v, err.Error()}, nil
@@ -146,17 +255,39 @@ func MakeHashEndpoint(srv Service) endpoint.Endpoint {
 
 
 func MakeValidateEndpoint(srv Service) endpoint.Endpoint {
-	
+
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		
+
 		req := request.(validateRequest)
 		v, err := srv.Validate(ctx, req.Password, req.Hash)
 
 		if err != nil {
-			return validateResponse{false, err.Error()}, nil
+			return validateResponse{Valid: false, Err: err.Error()}, nil
+		}
+
+		// req.Hash may carry a "vN$" pepper-version prefix (pepper.go) that
+		// hasher.AlgorithmOf/NeedsRehash don't understand - strip it first
+		// or every peppered hash looks unrecognized and NeedsRehash always
+		// comes back true.
+		_, h := decodeVersioned(req.Hash)
+
+		return validateResponse{Valid: v, NeedsRehash: v && hasher.NeedsRehash(h)}, nil
+	}
+}
+
+
+func MakeRotateEndpoint(srv Service) endpoint.Endpoint {
+
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+
+		req := request.(rotateRequest)
+		v, err := srv.Rotate(ctx, req.Password, req.OldHash)
+
+		if err != nil {
+			return rotateResponse{v, err.Error()}, nil
 		}
 
-		return validateResponse{v, ""}, nil
+		return rotateResponse{v, ""}, nil
 	}
 }
 
@@ -170,11 +301,12 @@ func MakeValidateEndpoint(srv Service) endpoint.Endpoint {
 type Endpoints struct {
 	HashEndpoint endpoint.Endpoint
 	ValidateEndpoint endpoint.Endpoint
+	RotateEndpoint endpoint.Endpoint
 }
 
-func (e Endpoints) Hash(ctx context.Context, password string) (string, error) {
+func (e Endpoints) Hash(ctx context.Context, password, algorithm string) (string, error) {
 
-	req := hashRequest{Password: password}
+	req := hashRequest{Password: password, Algorithm: algorithm}
 
 	resp, err := e.HashEndpoint(ctx, req)
 	if err != nil {
@@ -204,4 +336,21 @@ func (e Endpoints) Validate(ctx context.Context, password, hash string) (bool, e
 	}
 
 	return validateResp.Valid, nil
+}
+
+func (e Endpoints) Rotate(ctx context.Context, password, oldHash string) (string, error) {
+
+	req := rotateRequest{Password: password, OldHash: oldHash}
+
+	resp, err := e.RotateEndpoint(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	rotateResp := resp.(rotateResponse)
+	if rotateResp.Err != "" {
+		return "", errors.New(rotateResp.Err)
+	}
+
+	return rotateResp.Hash, nil
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package vault
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"Microservice/Vault/hasher"
+	"Microservice/Vault/keyprovider"
+)
+
+func TestServiceHashValidateRotateRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	srv := NewServiceWithKeys(hasher.Default(), keyprovider.NewEnv("pepper-key"))
+
+	hash, err := srv.Hash(ctx, "correct horse battery staple", "")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	valid, err := srv.Validate(ctx, "correct horse battery staple", hash)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !valid {
+		t.Fatalf("Validate(correct password, %q) = false, want true", hash)
+	}
+
+	valid, err = srv.Validate(ctx, "wrong password", hash)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if valid {
+		t.Fatalf("Validate(wrong password, %q) = true, want false", hash)
+	}
+
+	rotated, err := srv.Rotate(ctx, "correct horse battery staple", hash)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	valid, err = srv.Validate(ctx, "correct horse battery staple", rotated)
+	if err != nil {
+		t.Fatalf("Validate after Rotate: %v", err)
+	}
+	if !valid {
+		t.Fatalf("Validate(correct password, %q) after Rotate = false, want true", rotated)
+	}
+}
+
+func TestServiceRotateRejectsWrongPassword(t *testing.T) {
+	ctx := context.Background()
+	srv := NewService()
+
+	hash, err := srv.Hash(ctx, "correct horse battery staple", "")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	if _, err := srv.Rotate(ctx, "wrong password", hash); err == nil {
+		t.Fatalf("Rotate with the wrong password succeeded, want an error")
+	}
+}
+
+func TestServiceValidatesLegacyBcryptHash(t *testing.T) {
+	ctx := context.Background()
+
+	// A password hashed before the pepper/prefix conventions existed -
+	// a raw bcrypt hash with no "vN$" prefix at all.
+	bcryptHasher := hasher.NewBcrypt(4)
+	legacyHash, err := bcryptHasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	// The service now defaults to argon2id, but Validate must still
+	// dispatch to bcrypt based on the stored hash's own prefix.
+	srv := NewServiceWithHasher(hasher.Default())
+
+	valid, err := srv.Validate(ctx, "correct horse battery staple", legacyHash)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !valid {
+		t.Fatalf("Validate(correct password, %q) = false, want true", legacyHash)
+	}
+}